@@ -0,0 +1,236 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// ErrCompletion is returned by Eval when the hidden __complete command
+// was invoked and handled. Callers should treat it as a clean exit
+// rather than a parse failure.
+var ErrCompletion = errors.New("cli: completion requested")
+
+// completeKind selects how a flag's value is completed, opted into with
+// a `cli:"complete=..."` struct tag directive.
+type completeKind string
+
+const (
+	completeFiles  completeKind = "files"
+	completeDirs   completeKind = "dirs"
+	completeCustom completeKind = "custom"
+)
+
+// completeSpec is the parsed form of a `complete=...` tag directive.
+type completeSpec struct {
+	kind   completeKind
+	custom string
+}
+
+// CompleteContext is passed to a custom completer registered with
+// RegisterCompleter.
+type CompleteContext struct {
+	// Command is the name of the command/subcommand the flag belongs to.
+	Command string
+	// Flag is the flag's long name (e.g. "--region").
+	Flag string
+	// Prefix is the partial value already typed for this flag.
+	Prefix string
+}
+
+// Completer returns candidate completions for a CompleteContext.
+type Completer func(CompleteContext) []string
+
+// parseCompleteTag scans a raw `cli:"..."` struct tag for a
+// `complete=...` directive and returns the parsed spec.
+func parseCompleteTag(tg string) (completeSpec, bool) {
+	v, ok := tagDirectiveValue(tg, "complete")
+	if !ok {
+		return completeSpec{}, false
+	}
+	if name, custom, ok := strings.Cut(v, ":"); ok && name == "custom" {
+		return completeSpec{kind: completeCustom, custom: custom}, true
+	}
+	return completeSpec{kind: completeKind(v)}, true
+}
+
+// RegisterCompleter registers a named Completer, referenced from a
+// struct tag as `cli:"complete=custom:name"`.
+func (p *Parser) RegisterCompleter(name string, fn Completer) {
+	p.completers[name] = fn
+}
+
+// GenerateCompletion writes a shell completion script for shell
+// ("bash", "zsh" or "fish") to w, for the root command named cmdName.
+// The generated script shells out to "<prog> __complete ..." for every
+// completion request, so it always reflects the live command tree
+// (nested subcommands, enum values, custom completers) rather than a
+// snapshot baked in at generation time.
+func (p *Parser) GenerateCompletion(shell, cmdName string, w io.Writer) error {
+	if _, ok := p.cmds[cmdName]; !ok {
+		return ErrCommandNotFound(cmdName)
+	}
+	switch shell {
+	case "bash":
+		return generateBashCompletion(cmdName, w)
+	case "zsh":
+		return generateZshCompletion(cmdName, w)
+	case "fish":
+		return generateFishCompletion(cmdName, w, p.hasFileCompletion(p.cmds[cmdName]))
+	default:
+		return fmt.Errorf("cli: unsupported shell %q", shell)
+	}
+}
+
+// hasFileCompletion reports whether any flag in c's command tree is
+// tagged `cli:"complete=files"`/`cli:"complete=dirs"`, i.e. relies on the
+// shell's own filename-completion fallback rather than a custom one.
+func (p *Parser) hasFileCompletion(c *command) bool {
+	for _, a := range c.AllFlags() {
+		if spec, ok := p.completeArgs[a]; ok && (spec.kind == completeFiles || spec.kind == completeDirs) {
+			return true
+		}
+	}
+	for _, cc := range c.subcmd {
+		if p.hasFileCompletion(cc) {
+			return true
+		}
+	}
+	return false
+}
+
+func generateBashCompletion(name string, w io.Writer) error {
+	fn := "_" + name + "_completion"
+	fmt.Fprintf(w, "%s() {\n", fn)
+	fmt.Fprintf(w, "  local words=($(%s __complete \"${COMP_WORDS[@]:1:COMP_CWORD-1}\" \"${COMP_WORDS[COMP_CWORD]}\"))\n", name)
+	fmt.Fprintf(w, "  COMPREPLY=(\"${words[@]}\")\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "complete -F %s %s\n", fn, name)
+	return nil
+}
+
+func generateZshCompletion(name string, w io.Writer) error {
+	fmt.Fprintf(w, "#compdef %s\n", name)
+	fmt.Fprintf(w, "_%s() {\n", name)
+	fmt.Fprintf(w, "  local -a replies\n")
+	fmt.Fprintf(w, "  replies=(${(f)\"$(%s __complete ${words[2,-2]} ${words[-1]})\"})\n", name)
+	fmt.Fprintf(w, "  _describe 'command' replies\n")
+	fmt.Fprintf(w, "}\n")
+	fmt.Fprintf(w, "compdef _%s %s\n", name, name)
+	return nil
+}
+
+// generateFishCompletion registers completions for name, passing -f
+// (disabling fish's own filename-completion fallback) only when no flag
+// in the command tree is tagged `cli:"complete=files"`/`cli:"complete=dirs"` and
+// so relies on that fallback.
+func generateFishCompletion(name string, w io.Writer, needsFileFallback bool) error {
+	if needsFileFallback {
+		fmt.Fprintf(w, "complete -c %s -a '(%s __complete (commandline -opc) (commandline -ct))'\n", name, name)
+		return nil
+	}
+	fmt.Fprintf(w, "complete -c %s -f -a '(%s __complete (commandline -opc) (commandline -ct))'\n", name, name)
+	return nil
+}
+
+// complete returns completion candidates for the partial command line
+// words (not including the program/root command name itself), walking
+// down the command tree the same way Eval resolves subcommands.
+func (p *Parser) complete(c *command, words []string) []string {
+	if len(words) == 0 {
+		return p.completeNextToken(c, "")
+	}
+
+	for len(words) > 1 {
+		w := words[0]
+		if !isFlag(w) {
+			if cc, ok := c.subcmd[w]; ok {
+				c = cc
+				words = words[1:]
+				continue
+			}
+			break
+		}
+		flagName, _, hasValue := strings.Cut(w, "=")
+		if !hasValue {
+			if a := c.GetFlag(flagName); a != nil && !a.isBool() {
+				if len(words) == 2 {
+					// the last word is this flag's value being completed
+					break
+				}
+				// this flag consumed the next word as its value
+				words = words[1:]
+			}
+		}
+		words = words[1:]
+	}
+
+	last := words[len(words)-1]
+
+	// completing the value of "--flag <partial>" or "--flag=<partial>"
+	if flagName, val, ok := strings.Cut(last, "="); ok {
+		if a := c.GetFlag(flagName); a != nil {
+			return p.completeFlagValue(c, a, val)
+		}
+	}
+	if len(words) >= 2 && isFlag(words[len(words)-2]) {
+		if a := c.GetFlag(words[len(words)-2]); a != nil && !a.isBool() {
+			return p.completeFlagValue(c, a, last)
+		}
+	}
+
+	return p.completeNextToken(c, last)
+}
+
+func (p *Parser) completeNextToken(c *command, prefix string) []string {
+	var out []string
+	for name := range c.subcmd {
+		if strings.HasPrefix(name, prefix) {
+			out = append(out, name)
+		}
+	}
+	for _, a := range c.AllFlags() {
+		if a.positional {
+			continue
+		}
+		if strings.HasPrefix(a.long, prefix) {
+			out = append(out, a.long)
+		}
+		if a.short != "" && strings.HasPrefix(a.short, prefix) {
+			out = append(out, a.short)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (p *Parser) completeFlagValue(c *command, a *argument, prefix string) []string {
+	if a.enum {
+		var out []string
+		for k := range p.enums[a.typ] {
+			if strings.HasPrefix(k, prefix) {
+				out = append(out, k)
+			}
+		}
+		sort.Strings(out)
+		return out
+	}
+	spec, ok := p.completeArgs[a]
+	if !ok {
+		return nil
+	}
+	switch spec.kind {
+	case completeCustom:
+		fn, ok := p.completers[spec.custom]
+		if !ok {
+			return nil
+		}
+		return fn(CompleteContext{Command: c.name, Flag: a.long, Prefix: prefix})
+	default:
+		// "files"/"dirs" are left to the shell's own filename completion;
+		// we signal that by returning no candidates of our own.
+		return nil
+	}
+}
@@ -0,0 +1,168 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ErrHelp is returned by Eval when -h/--help was requested and handled.
+// Callers should treat it as a clean exit rather than a parse failure.
+var ErrHelp = errors.New("cli: help requested")
+
+// ErrVersion is returned by Eval when --version was requested and
+// handled. Callers should treat it as a clean exit rather than a parse
+// failure.
+var ErrVersion = errors.New("cli: version requested")
+
+// FlagContext is the template view of a single argument.
+type FlagContext struct {
+	Long       string
+	Short      string
+	Env        string
+	Help       string
+	Required   bool
+	Positional bool
+	Global     bool
+	Default    string
+	EnumValues []string
+	Source     string
+}
+
+// CommandContext is the template view of a command or subcommand.
+type CommandContext struct {
+	// Name is the full invocation path, e.g. "cmd sub" for a nested
+	// subcommand, as resolved by Eval for the current command line.
+	Name        string
+	Flags       []FlagContext
+	GlobalFlags []FlagContext
+	LocalFlags  []FlagContext
+	Positionals []FlagContext
+	Subcommands []string
+}
+
+// HelpContext is the root object passed to the help/man template.
+type HelpContext struct {
+	Version        string
+	GlobalsEnabled bool
+	Command        CommandContext
+}
+
+const defaultHelpTemplate = `{{if .Version}}{{.Command.Name}} {{.Version}}
+
+{{end}}Usage: {{.Command.Name}}{{range .Command.Positionals}} <{{.Long}}>{{end}}{{if .Command.Flags}} [flags]{{end}}{{if .Command.Subcommands}} <command>{{end}}
+
+{{define "flaglist"}}{{range .}}  {{.Long}}{{if .Short}}, {{.Short}}{{end}}{{if .Env}} ({{.Env}}){{end}}
+      {{.Help}}{{if .Required}} (required){{end}}{{if .Default}} (default {{.Default}}){{end}}{{if .EnumValues}} (one of: {{join .EnumValues ", "}}){{end}}
+{{end}}{{end}}{{if .GlobalsEnabled}}{{if .Command.LocalFlags}}Flags:
+{{template "flaglist" .Command.LocalFlags}}
+{{end}}{{if .Command.GlobalFlags}}Global Flags:
+{{template "flaglist" .Command.GlobalFlags}}
+{{end}}{{else}}{{if .Command.Flags}}Flags:
+{{template "flaglist" .Command.Flags}}
+{{end}}{{end}}{{if .Command.Subcommands}}Commands:
+{{range .Command.Subcommands}}  {{.}}
+{{end}}{{end}}`
+
+var helpFuncs = template.FuncMap{
+	"join": strings.Join,
+}
+
+func (p *Parser) buildCommandContext(c *command) CommandContext {
+	name := p.cmdPaths[c]
+	if name == "" {
+		name = c.name
+	}
+	cc := CommandContext{Name: name}
+	for _, a := range c.AllFlags() {
+		fc := FlagContext{
+			Long:       a.long,
+			Short:      a.short,
+			Env:        a.env,
+			Help:       a.help,
+			Required:   a.required,
+			Positional: a.positional,
+			Global:     p.globalArgs[a],
+			Default:    a.def,
+			Source:     p.argSources[a],
+		}
+		if a.enum {
+			em := p.enums[a.typ]
+			for k := range em {
+				fc.EnumValues = append(fc.EnumValues, k)
+			}
+			sort.Strings(fc.EnumValues)
+		}
+		if a.positional {
+			cc.Positionals = append(cc.Positionals, fc)
+			continue
+		}
+		cc.Flags = append(cc.Flags, fc)
+		if fc.Global {
+			cc.GlobalFlags = append(cc.GlobalFlags, fc)
+		} else {
+			cc.LocalFlags = append(cc.LocalFlags, fc)
+		}
+	}
+	for name := range c.subcmd {
+		cc.Subcommands = append(cc.Subcommands, name)
+	}
+	sort.Strings(cc.Subcommands)
+	return cc
+}
+
+// printHelp renders the help template for c to the parser's usage
+// writer.
+func (p *Parser) printHelp(c *command) error {
+	tmpl, err := template.New("help").Funcs(helpFuncs).Parse(p.helpTemplate)
+	if err != nil {
+		return fmt.Errorf("cli: parse help template: %w", err)
+	}
+	ctx := HelpContext{
+		Version:        p.version,
+		GlobalsEnabled: p.globalsEnabled,
+		Command:        p.buildCommandContext(c),
+	}
+	return tmpl.Execute(p.usageWriter, ctx)
+}
+
+// printVersion writes the configured version string to the parser's
+// usage writer.
+func (p *Parser) printVersion() {
+	fmt.Fprintln(p.usageWriter, p.version)
+}
+
+const defaultManTemplate = `.TH {{.Command.Name}} 1 "" "{{.Version}}" "User Commands"
+.SH NAME
+{{.Command.Name}}
+.SH SYNOPSIS
+.B {{.Command.Name}}
+{{range .Command.Flags}}[{{.Long}}]
+{{end}}
+.SH OPTIONS
+{{range .Command.Flags}}.TP
+.B {{.Long}}{{if .Short}}, {{.Short}}{{end}}
+{{.Help}}
+{{end}}`
+
+// Man renders a roff man page for c from the same HelpContext that
+// backs -h/--help. The roff template is built in; a template set with
+// WithHelpTemplate does not affect it.
+func (p *Parser) Man(c *command) (string, error) {
+	tmpl, err := template.New("man").Funcs(helpFuncs).Parse(defaultManTemplate)
+	if err != nil {
+		return "", fmt.Errorf("cli: parse man template: %w", err)
+	}
+	ctx := HelpContext{
+		Version:        p.version,
+		GlobalsEnabled: p.globalsEnabled,
+		Command:        p.buildCommandContext(c),
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, ctx); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// netrcRef is the machine/attribute a flag resolves its value from,
+// opted into via a `cli:"netrc=machine[:attr]"` struct tag directive.
+// attr defaults to "password" when not given, since netrc-backed flags
+// are almost always secrets.
+type netrcRef struct {
+	machine string
+	attr    string
+}
+
+// parseNetrcTag scans a raw `cli:"..."` struct tag for a `netrc=...`
+// directive and returns the referenced machine/attribute.
+func parseNetrcTag(tg string) (netrcRef, bool) {
+	v, ok := tagDirectiveValue(tg, "netrc")
+	if !ok {
+		return netrcRef{}, false
+	}
+	machine, attr, ok := strings.Cut(v, ":")
+	if !ok {
+		attr = "password"
+	}
+	return netrcRef{machine: machine, attr: attr}, true
+}
+
+type netrcMachine struct {
+	login    string
+	password string
+	account  string
+}
+
+// NetrcProvider is a ConfigProvider that resolves credentials from a
+// ".netrc"-formatted file, for flags tagged with `cli:"netrc=machine[:attr]"`.
+// It only ever answers for such flags; it does not participate in the
+// generic long-name/env-name provider lookup since netrc addressing is
+// two-dimensional (machine + attribute) rather than a flat key space.
+type NetrcProvider struct {
+	path     string
+	machines map[string]netrcMachine
+	lookup   func(machine string) (login, password string, err error)
+}
+
+// NetrcOption configures a NetrcProvider built by NewNetrcProvider.
+type NetrcOption func(*NetrcProvider)
+
+// WithNetrcPath overrides the netrc file path. Without it,
+// NewNetrcProvider falls back to $NETRC, then ~/.netrc.
+func WithNetrcPath(path string) NetrcOption {
+	return func(n *NetrcProvider) {
+		n.path = path
+	}
+}
+
+// WithNetrcLookup replaces file-based parsing entirely with fn, so
+// tests can inject a fake credential store without touching the
+// filesystem.
+func WithNetrcLookup(fn func(machine string) (login, password string, err error)) NetrcOption {
+	return func(n *NetrcProvider) {
+		n.lookup = fn
+	}
+}
+
+// NewNetrcProvider builds a NetrcProvider. With no options it reads
+// $NETRC, falling back to ~/.netrc; WithNetrcPath picks a specific file,
+// and WithNetrcLookup bypasses the filesystem altogether.
+func NewNetrcProvider(opts ...NetrcOption) (*NetrcProvider, error) {
+	n := &NetrcProvider{}
+	for _, o := range opts {
+		o(n)
+	}
+
+	if n.lookup != nil {
+		return n, nil
+	}
+
+	path := n.path
+	if path == "" {
+		path = netrcPath()
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Tolerate a missing netrc file: a flag backed by it only
+			// errors if it ends up required-but-unset, same as any
+			// other provider that has nothing to offer.
+			n.machines = map[string]netrcMachine{}
+			return n, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	n.machines, err = parseNetrc(f)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func netrcPath() string {
+	if p := os.Getenv("NETRC"); p != "" {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".netrc"
+	}
+	return home + "/.netrc"
+}
+
+// parseNetrc implements the small token grammar described in netrc(5):
+// whitespace-separated "machine"/"login"/"password"/"account" pairs,
+// terminated by the next "machine" or "default" token or EOF. "macdef"
+// blocks run until the next blank line and are skipped. "default"
+// entries are stored under the "" key, used by lookupMachine as a
+// fallback when a specific machine isn't found.
+func parseNetrc(r io.Reader) (map[string]netrcMachine, error) {
+	lines := bufio.NewScanner(r)
+
+	machines := map[string]netrcMachine{}
+	var cur string
+	var inMacdef bool
+	var pending []string
+
+	// nextToken walks blank-line-delimited lines of the file, splitting
+	// each into whitespace-separated tokens. This (rather than a plain
+	// bufio.ScanWords token stream) is what lets it notice the blank
+	// line that ends a macdef body, since ScanWords never surfaces
+	// blank lines at all.
+	nextToken := func() (string, bool) {
+		for {
+			if len(pending) > 0 {
+				tok := pending[0]
+				pending = pending[1:]
+				return tok, true
+			}
+			if !lines.Scan() {
+				return "", false
+			}
+			line := lines.Text()
+			if inMacdef {
+				if strings.TrimSpace(line) == "" {
+					inMacdef = false
+				}
+				continue
+			}
+			pending = strings.Fields(line)
+		}
+	}
+
+	for {
+		tok, ok := nextToken()
+		if !ok {
+			break
+		}
+		switch tok {
+		case "machine":
+			if v, ok := nextToken(); ok {
+				cur = v
+				machines[cur] = machines[cur]
+			}
+		case "default":
+			cur = ""
+		case "login":
+			if v, ok := nextToken(); ok {
+				m := machines[cur]
+				m.login = v
+				machines[cur] = m
+			}
+		case "password":
+			if v, ok := nextToken(); ok {
+				m := machines[cur]
+				m.password = v
+				machines[cur] = m
+			}
+		case "account":
+			if v, ok := nextToken(); ok {
+				m := machines[cur]
+				m.account = v
+				machines[cur] = m
+			}
+		case "macdef":
+			inMacdef = true
+			nextToken() // consume macro name
+		}
+	}
+	return machines, lines.Err()
+}
+
+func (n *NetrcProvider) lookupMachine(machine, attr string) (string, bool) {
+	if n.lookup != nil {
+		login, password, err := n.lookup(machine)
+		if err != nil {
+			return "", false
+		}
+		switch attr {
+		case "login":
+			return login, login != ""
+		case "account":
+			return "", false
+		default:
+			return password, password != ""
+		}
+	}
+
+	m, ok := n.machines[machine]
+	if !ok {
+		// fall back to the "default" entry, per netrc(5)
+		m, ok = n.machines[""]
+		if !ok {
+			return "", false
+		}
+	}
+	switch attr {
+	case "login":
+		return m.login, m.login != ""
+	case "account":
+		return m.account, m.account != ""
+	default:
+		return m.password, m.password != ""
+	}
+}
+
+// Lookup implements ConfigProvider for completeness, but NetrcProvider
+// is only ever consulted through its machine/attribute mapping for
+// flags tagged with `cli:"netrc=..."`; see applyProviders.
+func (n *NetrcProvider) Lookup(key string) (string, bool) {
+	machine, attr, ok := strings.Cut(key, ":")
+	if !ok {
+		attr = "password"
+	}
+	return n.lookupMachine(machine, attr)
+}
+
+// Name implements ConfigProvider.
+func (n *NetrcProvider) Name() string {
+	return "netrc"
+}
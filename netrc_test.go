@@ -0,0 +1,140 @@
+package cli
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseNetrc(t *testing.T) {
+	const data = `
+machine api.github.com
+  login octocat
+  password ghp_abc123
+
+machine internal.example.com
+  login svc
+  password secret
+  account eng
+
+default
+  login anon
+  password anon-pass
+`
+	machines, err := parseNetrc(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("parseNetrc: %v", err)
+	}
+
+	gh, ok := machines["api.github.com"]
+	if !ok {
+		t.Fatal("expected api.github.com entry")
+	}
+	if gh.login != "octocat" || gh.password != "ghp_abc123" {
+		t.Errorf("api.github.com = %+v", gh)
+	}
+
+	internal, ok := machines["internal.example.com"]
+	if !ok {
+		t.Fatal("expected internal.example.com entry")
+	}
+	if internal.account != "eng" {
+		t.Errorf("internal.example.com.account = %q, want eng", internal.account)
+	}
+
+	def, ok := machines[""]
+	if !ok {
+		t.Fatal("expected default entry under \"\"")
+	}
+	if def.login != "anon" || def.password != "anon-pass" {
+		t.Errorf("default = %+v", def)
+	}
+}
+
+func TestNetrcProviderLookupMachineFallsBackToDefault(t *testing.T) {
+	machines, err := parseNetrc(strings.NewReader(`
+machine api.github.com
+  login octocat
+  password ghp_abc123
+
+default
+  login anon
+  password anon-pass
+`))
+	if err != nil {
+		t.Fatalf("parseNetrc: %v", err)
+	}
+	n := &NetrcProvider{machines: machines}
+
+	if v, ok := n.lookupMachine("api.github.com", "password"); !ok || v != "ghp_abc123" {
+		t.Errorf("api.github.com password = %q, %v", v, ok)
+	}
+	if v, ok := n.lookupMachine("unknown.example.com", "password"); !ok || v != "anon-pass" {
+		t.Errorf("unknown machine should fall back to default, got %q, %v", v, ok)
+	}
+}
+
+func TestParseNetrcMacdefDoesNotSwallowLaterMachines(t *testing.T) {
+	machines, err := parseNetrc(strings.NewReader(`
+machine A
+  login a-user
+  password a-pass
+
+macdef greet
+echo hello
+echo world
+
+machine B
+  login b-user
+  password b-pass
+`))
+	if err != nil {
+		t.Fatalf("parseNetrc: %v", err)
+	}
+
+	if _, ok := machines["A"]; !ok {
+		t.Fatal("expected machine A")
+	}
+	b, ok := machines["B"]
+	if !ok {
+		t.Fatal("expected machine B to survive the macdef block")
+	}
+	if b.login != "b-user" || b.password != "b-pass" {
+		t.Errorf("machine B = %+v", b)
+	}
+}
+
+func TestNewNetrcProviderToleratesMissingFile(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "does-not-exist")
+
+	n, err := NewNetrcProvider(WithNetrcPath(missing))
+	if err != nil {
+		t.Fatalf("NewNetrcProvider should tolerate a missing file, got: %v", err)
+	}
+	if _, ok := n.lookupMachine("api.github.com", "password"); ok {
+		t.Error("expected no value from an empty netrc store")
+	}
+}
+
+func TestWithNetrcLookupBypassesFile(t *testing.T) {
+	n, err := NewNetrcProvider(WithNetrcLookup(func(machine string) (string, string, error) {
+		if machine != "api.github.com" {
+			return "", "", errors.New("unknown machine")
+		}
+		return "octocat", "ghp_abc123", nil
+	}))
+	if err != nil {
+		t.Fatalf("NewNetrcProvider: %v", err)
+	}
+
+	if v, ok := n.lookupMachine("api.github.com", "password"); !ok || v != "ghp_abc123" {
+		t.Errorf("password = %q, %v", v, ok)
+	}
+	if v, ok := n.lookupMachine("api.github.com", "login"); !ok || v != "octocat" {
+		t.Errorf("login = %q, %v", v, ok)
+	}
+	if _, ok := n.lookupMachine("nope.example.com", "password"); ok {
+		t.Error("expected lookup failure for unknown machine")
+	}
+}
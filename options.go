@@ -1,5 +1,7 @@
 package cli
 
+import "io"
+
 // ParserOption option type for Parser
 type ParserOption func(p *Parser)
 
@@ -50,4 +52,38 @@ func WithGlobalArgsEnabled() ParserOption {
 	return func(p *Parser) {
 		p.globalsEnabled = true
 	}
-}
\ No newline at end of file
+}
+
+// WithConfigProviders registers a chain of ConfigProvider sources that
+// fill flags left unset after CLI parsing. Providers run in the order
+// given; the first provider to produce a value for a flag wins, and an
+// explicit CLI flag always takes precedence over any provider.
+func WithConfigProviders(providers ...ConfigProvider) ParserOption {
+	return func(p *Parser) {
+		p.providers = append(p.providers, providers...)
+	}
+}
+
+// WithHelpTemplate overrides the default text/template used to render
+// -h/--help output. See HelpContext for the fields available to the
+// template. --man output always uses the built-in roff template.
+func WithHelpTemplate(tmpl string) ParserOption {
+	return func(p *Parser) {
+		p.helpTemplate = tmpl
+	}
+}
+
+// WithUsageWriter sets the writer help and usage text is rendered to.
+// Defaults to os.Stdout.
+func WithUsageWriter(w io.Writer) ParserOption {
+	return func(p *Parser) {
+		p.usageWriter = w
+	}
+}
+
+// WithVersion sets the version string printed by --version.
+func WithVersion(v string) ParserOption {
+	return func(p *Parser) {
+		p.version = v
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding"
 	"errors"
 	"fmt"
+	"io"
+	"os"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -28,18 +30,44 @@ type Parser struct {
 	cmdCase        Case
 	argSplicer     Splicer
 	envSplicer     Splicer
+	providers      []ConfigProvider
+	argSources     map[*argument]string
+	helpTemplate   string
+	usageWriter    io.Writer
+	version        string
+	globalArgs     map[*argument]bool
+	unitArgs       map[*argument]unitsMode
+	customUnits    []customUnit
+	netrcArgs      map[*argument]netrcRef
+	completeArgs   map[*argument]completeSpec
+	completers     map[string]Completer
+	reloadableArgs map[*argument]bool
+	onChangeFns    map[string][]func(old, new interface{})
+	reloadCh       chan []Change
+	cmdPaths       map[*command]string
 }
 
 // NewParser create new parser
 func NewParser(opts ...ParserOption) *Parser {
 	p := &Parser{
-		cmds:       map[string]*command{},
-		enums:      map[reflect.Type]map[string]interface{}{},
-		argCase:    CaseCamelLower,
-		envCase:    CaseSnakeUpper,
-		cmdCase:    CaseLower,
-		argSplicer: SplicerDot,
-		envSplicer: SplicerUnderscore,
+		cmds:           map[string]*command{},
+		enums:          map[reflect.Type]map[string]interface{}{},
+		argCase:        CaseCamelLower,
+		envCase:        CaseSnakeUpper,
+		cmdCase:        CaseLower,
+		argSplicer:     SplicerDot,
+		envSplicer:     SplicerUnderscore,
+		argSources:     map[*argument]string{},
+		helpTemplate:   defaultHelpTemplate,
+		usageWriter:    os.Stdout,
+		globalArgs:     map[*argument]bool{},
+		unitArgs:       map[*argument]unitsMode{},
+		netrcArgs:      map[*argument]netrcRef{},
+		completeArgs:   map[*argument]completeSpec{},
+		completers:     map[string]Completer{},
+		reloadableArgs: map[*argument]bool{},
+		onChangeFns:    map[string][]func(old, new interface{}){},
+		cmdPaths:       map[*command]string{},
 	}
 	for _, o := range opts {
 		o(p)
@@ -96,8 +124,28 @@ func (p *Parser) Eval(args []string) error {
 	}
 
 	p.execTree = append(p.execTree, c.path.Get())
+	if _, ok := p.cmdPaths[c]; !ok {
+		p.cmdPaths[c] = c.name
+	}
 
 	args = args[1:]
+
+	// hidden completion support: "<prog> __complete <partial words...>"
+	// prints candidates for shell completion scripts, and
+	// "<prog> completion <shell>" emits a ready-to-source script.
+	if len(args) > 0 && args[0] == "__complete" {
+		for _, cand := range p.complete(c, args[1:]) {
+			fmt.Fprintln(p.usageWriter, cand)
+		}
+		return ErrCompletion
+	}
+	if len(args) > 1 && args[0] == "completion" {
+		if err := p.GenerateCompletion(args[1], c.name, p.usageWriter); err != nil {
+			return err
+		}
+		return ErrCompletion
+	}
+
 	positional := false
 	positionals := []string{}
 	for i := 0; i < len(args); i++ {
@@ -123,8 +171,12 @@ func (p *Parser) Eval(args []string) error {
 				if err := p.setValues(values); err != nil {
 					return err
 				}
+				parentPath := p.cmdPaths[c]
 				c = cc
 				p.execTree = append(p.execTree, c.path.Get())
+				if _, ok := p.cmdPaths[c]; !ok {
+					p.cmdPaths[c] = parentPath + " " + c.name
+				}
 				continue
 			}
 			positionals = append(positionals, arg)
@@ -132,11 +184,24 @@ func (p *Parser) Eval(args []string) error {
 		}
 
 		if arg == "-h" || arg == "--help" {
-			// handle help
+			if err := p.printHelp(c); err != nil {
+				return err
+			}
+			return ErrHelp
 		}
 
 		if arg == "--version" {
-			// handle version
+			p.printVersion()
+			return ErrVersion
+		}
+
+		if arg == "--man" {
+			man, err := p.Man(c)
+			if err != nil {
+				return err
+			}
+			fmt.Fprint(p.usageWriter, man)
+			return ErrHelp
 		}
 
 		val := ""
@@ -206,6 +271,10 @@ func (p *Parser) Eval(args []string) error {
 		return err
 	}
 
+	if err := p.applyProviders(c); err != nil {
+		return err
+	}
+
 	for _, a := range c.AllFlags() {
 		if a.required && !a.isSet {
 			return fmt.Errorf("required flag not set: %s", a.long)
@@ -215,6 +284,66 @@ func (p *Parser) Eval(args []string) error {
 	return nil
 }
 
+// ArgSource reports which ConfigProvider (by Name()) supplied the
+// current value of the flag named long on command cmdName, and whether
+// it came from a provider at all. It returns ("", false) for flags set
+// explicitly on the command line, left at their zero value, or for an
+// unknown command/flag.
+func (p *Parser) ArgSource(cmdName, long string) (string, bool) {
+	c, ok := p.cmds[cmdName]
+	if !ok {
+		return "", false
+	}
+	for _, a := range c.AllFlags() {
+		if a.long == long {
+			src, ok := p.argSources[a]
+			return src, ok
+		}
+	}
+	return "", false
+}
+
+// applyProviders fills still-unset flags of c from the registered
+// ConfigProvider chain, in registration order. Explicit CLI flags always
+// win, and the first provider to produce a value for a flag wins over
+// later ones.
+func (p *Parser) applyProviders(c *command) error {
+	if len(p.providers) == 0 {
+		return nil
+	}
+	for _, a := range c.AllFlags() {
+		if a.isSet {
+			continue
+		}
+		for _, prov := range p.providers {
+			var val string
+			var ok bool
+
+			if np, isNetrc := prov.(*NetrcProvider); isNetrc {
+				ref, hasRef := p.netrcArgs[a]
+				if !hasRef {
+					continue
+				}
+				val, ok = np.lookupMachine(ref.machine, ref.attr)
+			} else {
+				val, ok = prov.Lookup(a.env)
+				if !ok {
+					val, ok = prov.Lookup(strings.TrimPrefix(a.long, "--"))
+				}
+			}
+			if !ok {
+				continue
+			}
+			if err := p.setValues(map[*argument][]string{a: {val}}); err != nil {
+				return err
+			}
+			p.argSources[a] = prov.Name()
+			break
+		}
+	}
+	return nil
+}
+
 func (p *Parser) setValues(values map[*argument][]string) error {
 	for a, s := range values {
 		a.isSet = true
@@ -239,6 +368,19 @@ func (p *Parser) setValues(values map[*argument][]string) error {
 			a.setValue(em[strings.ToLower(val)])
 			continue
 		}
+		// handle units (sizes, rates, durations) on numeric fields
+		if um, ok := p.unitArgs[a]; ok {
+			n, err := p.parseUnits(val, um)
+			if err != nil {
+				return fmt.Errorf("flag %s: %w", a.long, err)
+			}
+			cv, err := convertUnitValue(n, a.typ)
+			if err != nil {
+				return fmt.Errorf("flag %s: %w", a.long, err)
+			}
+			a.setValue(cv)
+			continue
+		}
 		// handle scalar
 		if err := a.setScalarValue(val); err != nil {
 			return err
@@ -278,6 +420,10 @@ func Execute(ctx context.Context) error {
 // Execute the chain of commands
 func (p *Parser) Execute(ctx context.Context) error {
 
+	if p.reloadCh != nil {
+		ctx = context.WithValue(ctx, reloadContextKey{}, (<-chan []Change)(p.reloadCh))
+	}
+
 	var err error
 	lastCmd := len(p.execTree) - 1
 	pPostRunners := []PersistentPostRunner{}
@@ -459,6 +605,7 @@ func (p *Parser) walkStruct(c *command, t reflect.Type, pth *path, pfx, envpfx s
 			}
 			globals.Add(name)
 		}
+		isGlobal := p.globalsEnabled && tag.global
 
 		// generate long and short flags
 		long := "--" + name
@@ -484,6 +631,26 @@ func (p *Parser) walkStruct(c *command, t reflect.Type, pth *path, pfx, envpfx s
 		}
 		c.AddArg(a)
 
+		if isGlobal {
+			p.globalArgs[a] = true
+		}
+
+		if um := parseUnitsTag(tg); um != "" {
+			p.unitArgs[a] = um
+		}
+
+		if ref, ok := parseNetrcTag(tg); ok {
+			p.netrcArgs[a] = ref
+		}
+
+		if spec, ok := parseCompleteTag(tg); ok {
+			p.completeArgs[a] = spec
+		}
+
+		if f.Tag.Get("reloadable") == "true" {
+			p.reloadableArgs[a] = true
+		}
+
 		// get the underlaying type if pointer
 		if isPtr(ft) {
 			ft = ft.Elem()
@@ -0,0 +1,265 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfigProvider supplies values for flags that were not set explicitly
+// on the command line. Lookup is keyed by the same long-name/env-name
+// strings that walkStruct computes for each argument (the "--" prefix is
+// stripped from the long name before lookup).
+type ConfigProvider interface {
+	// Lookup returns the raw string value for key, and whether it was
+	// found. key is tried first as the argument's env name, then its
+	// long name.
+	Lookup(key string) (string, bool)
+	// Name identifies the provider, mostly for diagnostics such as
+	// argument source tracking.
+	Name() string
+}
+
+// Decoder turns raw config file bytes into a flat key/value map. sep
+// joins nested keys (e.g. "a.b" or "a_b") and should be the same Splicer
+// the Parser uses for argument long names (see WithArgSplicer), so
+// nested file keys line up with the flat key space walkStruct produces.
+type Decoder func(data []byte, sep Splicer) (map[string]string, error)
+
+var fileDecoders = map[string]Decoder{
+	".json": decodeJSON,
+	".yaml": decodeYAML,
+	".yml":  decodeYAML,
+	".toml": decodeTOML,
+}
+
+// RegisterDecoder registers a Decoder for a file extension (including the
+// leading dot, e.g. ".yaml"). Use this to add other formats, or to
+// replace the built-in subset YAML/TOML decoders with a full library,
+// without forcing every consumer of this package to vendor those
+// libraries.
+func RegisterDecoder(ext string, dec Decoder) {
+	fileDecoders[ext] = dec
+}
+
+func decodeJSON(data []byte, sep Splicer) (map[string]string, error) {
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	out := map[string]string{}
+	flattenMap("", raw, out, sep)
+	return out, nil
+}
+
+// decodeYAML supports the subset of YAML this package needs to populate
+// flags: indentation-nested mappings of scalar values. Sequences and
+// flow-style collections are not supported; use RegisterDecoder to plug
+// in a full YAML library if you need them.
+func decodeYAML(data []byte, sep Splicer) (map[string]string, error) {
+	out := map[string]string{}
+	var stack []string
+	var indents []int
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+
+		for len(indents) > 0 && indent <= indents[len(indents)-1] {
+			indents = indents[:len(indents)-1]
+			stack = stack[:len(stack)-1]
+		}
+
+		k, v, ok := strings.Cut(trimmed, ":")
+		if !ok {
+			continue
+		}
+		key := strings.TrimSpace(k)
+		val := strings.TrimSpace(v)
+
+		if val == "" {
+			stack = append(stack, key)
+			indents = append(indents, indent)
+			continue
+		}
+
+		val = strings.Trim(val, `"'`)
+		fullKey := key
+		for i := len(stack) - 1; i >= 0; i-- {
+			fullKey = sep.Splice(stack[i], fullKey)
+		}
+		out[fullKey] = val
+	}
+	return out, scanner.Err()
+}
+
+// decodeTOML supports the subset of TOML this package needs: top-level
+// and `[section]`/`[section.sub]` scalar key = value pairs. Arrays and
+// inline tables are not supported; use RegisterDecoder to plug in a full
+// TOML library if you need them.
+func decodeTOML(data []byte, sep Splicer) (map[string]string, error) {
+	out := map[string]string{}
+	section := ""
+
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key := strings.TrimSpace(k)
+		val := strings.Trim(strings.TrimSpace(v), `"'`)
+		if section != "" {
+			parts := strings.Split(section, ".")
+			for i := len(parts) - 1; i >= 0; i-- {
+				key = sep.Splice(strings.TrimSpace(parts[i]), key)
+			}
+		}
+		out[key] = val
+	}
+	return out, scanner.Err()
+}
+
+func flattenMap(pfx string, in map[string]interface{}, out map[string]string, sep Splicer) {
+	for k, v := range in {
+		key := k
+		if pfx != "" {
+			key = sep.Splice(pfx, k)
+		}
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			flattenMap(key, vv, out, sep)
+		default:
+			out[key] = fmt.Sprintf("%v", vv)
+		}
+	}
+}
+
+// fileProvider is a ConfigProvider backed by a single structured config
+// file, decoded according to its extension.
+type fileProvider struct {
+	path   string
+	values map[string]string
+}
+
+// NewFileProvider reads and decodes the config file at path using the
+// Decoder registered for its extension. ".json", ".yaml"/".yml" and
+// ".toml" are wired in by default; RegisterDecoder can add more. sep
+// joins the file's nested keys into the same flat key space walkStruct
+// produces for argument long names, so it should be the Parser's
+// argSplicer (the same value passed to WithArgSplicer, or SplicerDot if
+// that option wasn't used).
+func NewFileProvider(path string, sep Splicer) (ConfigProvider, error) {
+	dec, ok := fileDecoders[strings.ToLower(filepath.Ext(path))]
+	if !ok {
+		return nil, fmt.Errorf("cli: no decoder registered for %s", filepath.Ext(path))
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values, err := dec(data, sep)
+	if err != nil {
+		return nil, fmt.Errorf("cli: decode %s: %w", path, err)
+	}
+	return &fileProvider{path: path, values: values}, nil
+}
+
+func (f *fileProvider) Lookup(key string) (string, bool) {
+	v, ok := f.values[key]
+	return v, ok
+}
+
+func (f *fileProvider) Name() string {
+	return "file:" + f.path
+}
+
+// dotEnvProvider is a ConfigProvider backed by a ".env"-style file of
+// KEY=VALUE lines.
+type dotEnvProvider struct {
+	path   string
+	prefix string
+	values map[string]string
+}
+
+// NewDotEnvProvider reads a dotenv file at path. If prefix is non-empty,
+// only keys with that prefix are considered, and the prefix is stripped
+// before matching against an argument's env name (mirroring EnvPrefix
+// semantics for NewEnvProvider).
+func NewDotEnvProvider(path string, prefix string) (ConfigProvider, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := map[string]string{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		i := strings.Index(line, "=")
+		if i == -1 {
+			continue
+		}
+		k := strings.TrimSpace(line[:i])
+		v := strings.Trim(strings.TrimSpace(line[i+1:]), `"'`)
+		if prefix != "" {
+			if !strings.HasPrefix(k, prefix) {
+				continue
+			}
+			k = strings.TrimPrefix(k, prefix)
+		}
+		values[k] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return &dotEnvProvider{path: path, prefix: prefix, values: values}, nil
+}
+
+func (d *dotEnvProvider) Lookup(key string) (string, bool) {
+	v, ok := d.values[key]
+	return v, ok
+}
+
+func (d *dotEnvProvider) Name() string {
+	return "dotenv:" + d.path
+}
+
+// envProvider is a ConfigProvider backed by the process environment.
+type envProvider struct {
+	prefix string
+}
+
+// NewEnvProvider builds a ConfigProvider that reads from os.Environ,
+// honoring EnvPrefix the same way dotenv does.
+func NewEnvProvider(prefix string) ConfigProvider {
+	return &envProvider{prefix: prefix}
+}
+
+func (e *envProvider) Lookup(key string) (string, bool) {
+	return os.LookupEnv(e.prefix + key)
+}
+
+func (e *envProvider) Name() string {
+	return "env"
+}
@@ -0,0 +1,38 @@
+package cli
+
+import "strings"
+
+// tagDirective is one key[=value] segment of a raw `cli:"..."` struct
+// tag, as produced by parseTagDirectives.
+type tagDirective struct {
+	key   string
+	value string
+}
+
+// parseTagDirectives splits a raw struct tag into its comma-separated
+// key[=value] directives, trimming whitespace from each part. It is the
+// single shared scanner behind parseUnitsTag, parseNetrcTag and
+// parseCompleteTag, which previously each re-implemented this split.
+func parseTagDirectives(tg string) []tagDirective {
+	var out []tagDirective
+	for _, part := range strings.Split(tg, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, _ := strings.Cut(part, "=")
+		out = append(out, tagDirective{key: k, value: v})
+	}
+	return out
+}
+
+// tagDirectiveValue returns the value of the first directive named key in
+// tg, and whether it was present at all.
+func tagDirectiveValue(tg, key string) (string, bool) {
+	for _, d := range parseTagDirectives(tg) {
+		if d.key == key {
+			return d.value, true
+		}
+	}
+	return "", false
+}
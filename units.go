@@ -0,0 +1,182 @@
+package cli
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// unitsMode selects how a numeric flag's string value is interpreted by
+// parseUnits. It is opted into per-field with a `cli:"units=..."` struct
+// tag directive.
+type unitsMode string
+
+const (
+	// unitsSI interprets decimal size/rate suffixes: kB, MB, GB, TB (and
+	// their /s rate variants).
+	unitsSI unitsMode = "si"
+	// unitsIEC interprets binary size/rate suffixes: KiB, MiB, GiB, TiB
+	// (and their /s rate variants).
+	unitsIEC unitsMode = "iec"
+	// unitsBytes accepts either SI or IEC suffixes, defaulting to bytes
+	// when none is given.
+	unitsBytes unitsMode = "bytes"
+	// unitsDuration interprets Go-style durations (2h30m) and stores the
+	// result as nanoseconds.
+	unitsDuration unitsMode = "duration"
+)
+
+// parseUnitsTag scans a raw `cli:"..."` struct tag for a `units=...`
+// directive and returns the corresponding unitsMode, or "" if none is
+// present or the value is unrecognized.
+func parseUnitsTag(tg string) unitsMode {
+	v, ok := tagDirectiveValue(tg, "units")
+	if !ok {
+		return ""
+	}
+	switch unitsMode(v) {
+	case unitsSI, unitsIEC, unitsBytes, unitsDuration:
+		return unitsMode(v)
+	}
+	return ""
+}
+
+var siSuffixes = []struct {
+	suffix string
+	mult   int64
+}{
+	{"TB", 1e12}, {"GB", 1e9}, {"MB", 1e6}, {"kB", 1e3}, {"B", 1},
+}
+
+var iecSuffixes = []struct {
+	suffix string
+	mult   int64
+}{
+	{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}, {"B", 1},
+}
+
+// customUnit is a user-registered unit suffix/scale pair, added via
+// Parser.RegisterUnit.
+type customUnit struct {
+	name  string
+	scale uint64
+}
+
+// RegisterUnit adds a custom unit suffix (e.g. "blk" for 512-byte disk
+// blocks) that parseUnits recognizes in addition to the built-in SI/IEC
+// size suffixes and Go-style durations, for flags tagged
+// `cli:"units=..."`. Longer suffixes are matched before shorter ones, so
+// registering overlapping suffixes (e.g. "req" and "kreq") is safe.
+func (p *Parser) RegisterUnit(name string, scale uint64) {
+	p.customUnits = append(p.customUnits, customUnit{name: name, scale: scale})
+	sort.Slice(p.customUnits, func(i, j int) bool {
+		return len(p.customUnits[i].name) > len(p.customUnits[j].name)
+	})
+}
+
+// parseUnits parses s according to mode, returning the value in base
+// units (bytes, or nanoseconds for unitsDuration). A trailing "/s" is
+// accepted and ignored (rates are expressed as a base-unit count, the
+// "per second" is purely documentation for the flag). Custom units
+// registered via RegisterUnit are tried before the built-in tables.
+func (p *Parser) parseUnits(s string, mode unitsMode) (int64, error) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimSuffix(s, "/s")
+
+	for _, cu := range p.customUnits {
+		if !strings.HasSuffix(s, cu.name) {
+			continue
+		}
+		numStr := strings.TrimSpace(strings.TrimSuffix(s, cu.name))
+		f, err := strconv.ParseFloat(numStr, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid value %q: %w", s, err)
+		}
+		return int64(f * float64(cu.scale)), nil
+	}
+
+	if mode == unitsDuration {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q: %w", s, err)
+		}
+		return int64(d), nil
+	}
+
+	suffixes := siSuffixes
+	if mode == unitsIEC {
+		suffixes = iecSuffixes
+	}
+	if mode == unitsBytes {
+		if n, err := p.parseUnits(s, unitsIEC); err == nil {
+			return n, nil
+		}
+		return p.parseUnits(s, unitsSI)
+	}
+
+	for _, su := range suffixes {
+		if strings.HasSuffix(s, su.suffix) {
+			numStr := strings.TrimSuffix(s, su.suffix)
+			f, err := strconv.ParseFloat(strings.TrimSpace(numStr), 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid value %q: %w", s, err)
+			}
+			return int64(f * float64(su.mult)), nil
+		}
+	}
+
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q: %w", s, err)
+	}
+	return n, nil
+}
+
+// UnitOverflowError is returned by convertUnitValue when a parsed
+// size/rate/duration value does not fit the target field's integer
+// type, e.g. "1MiB" into a uint16.
+type UnitOverflowError struct {
+	Value int64
+	Type  reflect.Type
+}
+
+func (e *UnitOverflowError) Error() string {
+	return fmt.Sprintf("cli: value %d overflows %s", e.Value, e.Type)
+}
+
+// convertUnitValue converts n to t's underlying kind (int/uint family),
+// so it can be passed to argument.setValue. It returns a
+// *UnitOverflowError if n does not fit in t.
+func convertUnitValue(n int64, t reflect.Type) (interface{}, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	v := reflect.New(t).Elem()
+	if isUint(t) {
+		bits := t.Bits()
+		max := uint64(math.MaxUint64)
+		if bits < 64 {
+			max = (uint64(1) << uint(bits)) - 1
+		}
+		if n < 0 || uint64(n) > max {
+			return nil, &UnitOverflowError{Value: n, Type: t}
+		}
+		v.SetUint(uint64(n))
+	} else {
+		bits := t.Bits()
+		min, max := int64(math.MinInt64), int64(math.MaxInt64)
+		if bits < 64 {
+			max = int64(1)<<uint(bits-1) - 1
+			min = -(int64(1) << uint(bits-1))
+		}
+		if n < min || n > max {
+			return nil, &UnitOverflowError{Value: n, Type: t}
+		}
+		v.SetInt(n)
+	}
+	return v.Interface(), nil
+}
@@ -0,0 +1,211 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// reloadDebounce is how long ConfigWatcher waits for a burst of
+// filesystem events (editors commonly write+rename+chmod per save) to
+// settle before decoding and applying a reload.
+const reloadDebounce = 100 * time.Millisecond
+
+// Change describes a single config value that changed between reloads of
+// a watched file.
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+type reloadContextKey struct{}
+
+// ReloadFromContext returns the channel of live config changes for the
+// current execution, if WatchConfig was ever called on the Parser that
+// built ctx's execution tree. A Runner can select on this channel to
+// react to config reloads while it is running.
+func ReloadFromContext(ctx context.Context) (<-chan []Change, bool) {
+	ch, ok := ctx.Value(reloadContextKey{}).(<-chan []Change)
+	return ch, ok
+}
+
+// ConfigWatcher live-reloads a structured config file and applies
+// changed values to the flags of a command, notifying callbacks with a
+// typed set of changes on every reload. Only fields tagged
+// `reloadable:"true"` are ever overwritten after startup; all other
+// flags keep whatever value they had when first set, to protect
+// invariants a Runner assumed were fixed for its lifetime.
+type ConfigWatcher struct {
+	parser   *Parser
+	path     string
+	cmd      *command
+	onChange func([]Change)
+	watcher  *fsnotify.Watcher
+
+	mu        sync.Mutex
+	prev      map[string]string
+	debounce  *time.Timer
+	pendingMu sync.Mutex
+}
+
+// WatchConfig starts watching the structured config file at path (using
+// the same Decoder registry as NewFileProvider) and applies changed
+// values for cmdName's `reloadable:"true"` flags as they happen,
+// debounced by 100ms and invoking onChange with the resulting set of
+// changes after each reload. Per-field callbacks registered with
+// Parser.OnChange fire too, and any Runner observing its context via
+// ReloadFromContext sees the same changes. The returned ConfigWatcher
+// must be closed to stop watching.
+func (p *Parser) WatchConfig(cmdName, path string, onChange func([]Change)) (*ConfigWatcher, error) {
+	c, ok := p.cmds[cmdName]
+	if !ok {
+		return nil, ErrCommandNotFound(cmdName)
+	}
+
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := fw.Add(filepath.Dir(path)); err != nil {
+		fw.Close()
+		return nil, err
+	}
+
+	if p.reloadCh == nil {
+		p.reloadCh = make(chan []Change, 1)
+	}
+
+	cw := &ConfigWatcher{
+		parser:   p,
+		path:     path,
+		cmd:      c,
+		onChange: onChange,
+		watcher:  fw,
+		prev:     map[string]string{},
+	}
+
+	if values, err := cw.decode(); err == nil {
+		cw.prev = values
+	}
+
+	go cw.run()
+
+	return cw, nil
+}
+
+// OnChange registers fn to run whenever a live-reloaded config file
+// changes the value of the flag with long-name/env-name fieldPath. fn
+// receives the old and new raw string values. Like ConfigWatcher
+// itself, it only ever fires for `reloadable:"true"` fields.
+func (p *Parser) OnChange(fieldPath string, fn func(old, new interface{})) {
+	p.onChangeFns[fieldPath] = append(p.onChangeFns[fieldPath], fn)
+}
+
+// Close stops the watcher.
+func (cw *ConfigWatcher) Close() error {
+	cw.pendingMu.Lock()
+	if cw.debounce != nil {
+		cw.debounce.Stop()
+	}
+	cw.pendingMu.Unlock()
+	return cw.watcher.Close()
+}
+
+func (cw *ConfigWatcher) decode() (map[string]string, error) {
+	dec, ok := fileDecoders[strings.ToLower(filepath.Ext(cw.path))]
+	if !ok {
+		return nil, fmt.Errorf("cli: no decoder registered for %s", filepath.Ext(cw.path))
+	}
+	data, err := os.ReadFile(cw.path)
+	if err != nil {
+		return nil, err
+	}
+	return dec(data, cw.parser.argSplicer)
+}
+
+func (cw *ConfigWatcher) run() {
+	for event := range cw.watcher.Events {
+		if filepath.Clean(event.Name) != filepath.Clean(cw.path) {
+			continue
+		}
+		if !(event.Op&fsnotify.Write == fsnotify.Write || event.Op&fsnotify.Create == fsnotify.Create) {
+			continue
+		}
+		cw.scheduleReload()
+	}
+}
+
+// scheduleReload debounces bursts of fsnotify events into a single
+// reload, fired reloadDebounce after the last event in the burst.
+func (cw *ConfigWatcher) scheduleReload() {
+	cw.pendingMu.Lock()
+	defer cw.pendingMu.Unlock()
+	if cw.debounce != nil {
+		cw.debounce.Stop()
+	}
+	cw.debounce = time.AfterFunc(reloadDebounce, cw.reload)
+}
+
+func (cw *ConfigWatcher) reload() {
+	values, err := cw.decode()
+	if err != nil {
+		return
+	}
+
+	cw.mu.Lock()
+	var changes []Change
+	toSet := map[*argument][]string{}
+	for _, a := range cw.cmd.AllFlags() {
+		if !cw.parser.reloadableArgs[a] {
+			continue
+		}
+		key := a.env
+		if _, ok := values[key]; !ok {
+			key = strings.TrimPrefix(a.long, "--")
+		}
+		newVal, ok := values[key]
+		if !ok {
+			continue
+		}
+		oldVal := cw.prev[key]
+		if newVal == oldVal {
+			continue
+		}
+		changes = append(changes, Change{Key: key, Old: oldVal, New: newVal})
+		toSet[a] = []string{newVal}
+	}
+	cw.prev = values
+	cw.mu.Unlock()
+
+	if len(changes) == 0 {
+		return
+	}
+
+	if err := cw.parser.setValues(toSet); err != nil {
+		return
+	}
+
+	for _, ch := range changes {
+		for _, fn := range cw.parser.onChangeFns[ch.Key] {
+			fn(ch.Old, ch.New)
+		}
+	}
+
+	if cw.parser.reloadCh != nil {
+		select {
+		case cw.parser.reloadCh <- changes:
+		default:
+		}
+	}
+
+	if cw.onChange != nil {
+		cw.onChange(changes)
+	}
+}